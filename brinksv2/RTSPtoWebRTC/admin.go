@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// serveAdmin starts the admin HTTP surface on its own listener, separate
+// from the viewer-facing server started by serveHTTP. Keeping it on a
+// distinct address means it can be firewalled off from the public internet.
+func serveAdmin(cfg *AppConfig) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/reload", reloadHandler)
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", readyzHandler)
+	mux.HandleFunc("/debug", debugHandler(cfg.Admin.Token))
+	mux.HandleFunc("/resolve/", resolveHandler)
+
+	slog.Info("Admin server listening", "addr", cfg.Admin.ListenAddr)
+	if err := http.ListenAndServe(cfg.Admin.ListenAddr, mux); err != nil {
+		slog.Error("Admin server stopped", "error", err)
+	}
+}
+
+// reloadHandler triggers the same reconcile path used by the notification
+// listener and the periodic fallback, on demand.
+func reloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := LoadCamerasFromDB(); err != nil {
+		slog.Error("Error reloading cameras via /admin/reload", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// healthzHandler is a liveness probe: it only reports whether the process is
+// still running, not whether it's useful yet.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// readyzHandler is a readiness probe: it reports 200 only once the camera
+// list has been loaded at least once and at least one stream is configured
+// (see ready in readiness.go for why it doesn't also gate on frame freshness
+// yet).
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	if !ready() {
+		http.Error(w, "not ready\n", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// resolveHandler applies the ABR quality selection in streaming.go to a bare
+// camera id (GET /resolve/cameraN?quality=main or ?bw=<bps>) and returns the
+// concrete Config.Streams key a viewer-facing handler should actually look
+// up. This is the real call site for QualityFromRequest/ResolveStreamID;
+// the WebRTC/WHEP signaling itself is served by serveHTTP elsewhere.
+func resolveHandler(w http.ResponseWriter, r *http.Request) {
+	baseID := strings.TrimPrefix(r.URL.Path, "/resolve/")
+	if baseID == "" {
+		http.Error(w, "missing camera id", http.StatusBadRequest)
+		return
+	}
+
+	quality := QualityFromRequest(r)
+	streamID := ResolveStreamID(baseID, quality)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{
+		"camera_id": baseID,
+		"quality":   quality,
+		"stream_id": streamID,
+	}); err != nil {
+		slog.Error("Error encoding /resolve response", "error", err)
+	}
+}
+
+// constantTimeEquals compares got against want in constant time so a
+// mistyped/guessed admin token can't be brute-forced by timing how fast
+// the comparison fails. subtle.ConstantTimeCompare already returns 0 for
+// differing lengths (its timing depends on the lengths, not on the
+// contents), so no separate length check is needed before calling it.
+func constantTimeEquals(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// debugHandler dumps the current Config.Streams map as JSON for
+// troubleshooting. It is gated behind Admin.Token, sent as a "token" query
+// param or an X-Admin-Token header, the same pattern chihaya uses for its
+// admin_token-gated endpoints. An empty token disables the endpoint.
+func debugHandler(token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.Error(w, "debug endpoint disabled", http.StatusForbidden)
+			return
+		}
+		if !constantTimeEquals(r.URL.Query().Get("token"), token) && !constantTimeEquals(r.Header.Get("X-Admin-Token"), token) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		Config.mutex.Lock()
+		streams := make(map[string]StreamST, len(Config.Streams))
+		for id, stream := range Config.Streams {
+			streams[id] = stream
+		}
+		Config.mutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(streams); err != nil {
+			slog.Error("Error encoding /debug response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConstantTimeEquals(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+		ok   bool
+	}{
+		{"match", "s3cret", "s3cret", true},
+		{"mismatch same length", "s3cret", "s3cres", false},
+		{"mismatch different length", "s3cre", "s3cret", false},
+		{"both empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := constantTimeEquals(tt.got, tt.want); got != tt.ok {
+				t.Errorf("constantTimeEquals(%q, %q) = %v, want %v", tt.got, tt.want, got, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDebugHandlerRejectsWrongToken(t *testing.T) {
+	handler := debugHandler("s3cret")
+
+	req := httptest.NewRequest(http.MethodGet, "/debug?token=wrong", nil)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestDebugHandlerAcceptsHeaderToken(t *testing.T) {
+	resetCameraState(t)
+	handler := debugHandler("s3cret")
+
+	req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+	req.Header.Set("X-Admin-Token", "s3cret")
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// AppConfig holds all runtime configuration for the RTSP-to-WebRTC bridge.
+// It is loaded once at startup from a TOML file (see LoadAppConfig) and is
+// read-only afterwards; live camera changes flow through Config.Streams
+// instead of through this struct.
+type AppConfig struct {
+	Database  DatabaseConfig  `toml:"database"`
+	Admin     AdminConfig     `toml:"admin"`
+	Logging   LoggingConfig   `toml:"logging"`
+	Streaming StreamingConfig `toml:"streaming"`
+}
+
+// StreamingConfig tunes the main/sub ABR behavior.
+type StreamingConfig struct {
+	// MainIdleWindowSeconds is how long a _main stream can sit with zero
+	// viewers before it's switched to OnDemand to save upstream bandwidth.
+	MainIdleWindowSeconds int `toml:"main_idle_window_seconds"`
+}
+
+func (s StreamingConfig) mainIdleWindow() time.Duration {
+	if s.MainIdleWindowSeconds <= 0 {
+		return 2 * time.Minute
+	}
+	return time.Duration(s.MainIdleWindowSeconds) * time.Second
+}
+
+// DatabaseConfig describes how to reach the database shared with the
+// FastAPI backend. Driver selects which GORM dialector InitDB uses, so the
+// same binary can run against Postgres in production and SQLite in dev.
+type DatabaseConfig struct {
+	Driver   string `toml:"driver"` // "postgres", "mysql", or "sqlite"
+	Host     string `toml:"host"`
+	Port     int    `toml:"port"`
+	User     string `toml:"user"`
+	Password string `toml:"password"`
+	Name     string `toml:"name"`
+	SSLMode  string `toml:"sslmode"`
+}
+
+// AdminConfig controls the small admin HTTP surface (manual reload, etc).
+type AdminConfig struct {
+	ListenAddr               string `toml:"listen_addr"`
+	ReconcileIntervalSeconds int    `toml:"reconcile_interval_seconds"`
+	// Token gates /debug; requests must send it as a "token" query param or
+	// an "X-Admin-Token" header. Empty disables /debug entirely.
+	Token string `toml:"token"`
+}
+
+const defaultConfigPath = "config.toml"
+
+// LoadAppConfig reads AppConfig from CONFIG_PATH (default "config.toml"),
+// falling back to built-in defaults for anything the file doesn't set, and
+// finally letting a handful of DB_* / ADMIN_* / LOG_LEVEL environment variables override
+// both. Missing files are not an error - a deployment may rely on env vars
+// alone.
+func LoadAppConfig() (*AppConfig, error) {
+	path := os.Getenv("CONFIG_PATH")
+	if path == "" {
+		path = defaultConfigPath
+	}
+
+	cfg := defaultAppConfig()
+
+	if _, err := os.Stat(path); err == nil {
+		if _, err := toml.DecodeFile(path, cfg); err != nil {
+			return nil, fmt.Errorf("error parsing config file %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading config file %s: %v", path, err)
+	}
+
+	applyEnvOverrides(cfg)
+
+	return cfg, nil
+}
+
+func defaultAppConfig() *AppConfig {
+	return &AppConfig{
+		Database: DatabaseConfig{
+			Driver:  "postgres",
+			Host:    "127.0.0.1",
+			Port:    5432,
+			Name:    "razz",
+			SSLMode: "disable",
+		},
+		Admin: AdminConfig{
+			ListenAddr:               ":8090",
+			ReconcileIntervalSeconds: 30,
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		Streaming: StreamingConfig{
+			MainIdleWindowSeconds: 120,
+		},
+	}
+}
+
+func applyEnvOverrides(cfg *AppConfig) {
+	if v := os.Getenv("DB_DRIVER"); v != "" {
+		cfg.Database.Driver = v
+	}
+	if v := os.Getenv("DB_HOST"); v != "" {
+		cfg.Database.Host = v
+	}
+	if v := os.Getenv("DB_USER"); v != "" {
+		cfg.Database.User = v
+	}
+	if v := os.Getenv("DB_PASSWORD"); v != "" {
+		cfg.Database.Password = v
+	}
+	if v := os.Getenv("DB_NAME"); v != "" {
+		cfg.Database.Name = v
+	}
+	if v := os.Getenv("DB_SSLMODE"); v != "" {
+		cfg.Database.SSLMode = v
+	}
+	if v := os.Getenv("ADMIN_LISTEN_ADDR"); v != "" {
+		cfg.Admin.ListenAddr = v
+	}
+	if v := os.Getenv("ADMIN_TOKEN"); v != "" {
+		cfg.Admin.Token = v
+	}
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		cfg.Logging.Level = v
+	}
+}
+
+// dsn renders the Postgres connection string used by the postgres GORM
+// dialector and by pq.Listener for LISTEN/NOTIFY.
+func (d DatabaseConfig) dsn() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode)
+}
+
+// mysqlDSN renders the DSN used by the mysql GORM dialector.
+func (d DatabaseConfig) mysqlDSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		d.User, d.Password, d.Host, d.Port, d.Name)
+}
+
+func (a AdminConfig) reconcileInterval() time.Duration {
+	if a.ReconcileIntervalSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(a.ReconcileIntervalSeconds) * time.Second
+}
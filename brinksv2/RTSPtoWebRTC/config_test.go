@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAppConfigDefaults(t *testing.T) {
+	t.Setenv("CONFIG_PATH", filepath.Join(t.TempDir(), "does-not-exist.toml"))
+
+	cfg, err := LoadAppConfig()
+	if err != nil {
+		t.Fatalf("LoadAppConfig() error = %v, want nil", err)
+	}
+
+	if cfg.Database.Driver != "postgres" {
+		t.Errorf("Database.Driver = %q, want %q", cfg.Database.Driver, "postgres")
+	}
+	if cfg.Admin.ListenAddr != ":8090" {
+		t.Errorf("Admin.ListenAddr = %q, want %q", cfg.Admin.ListenAddr, ":8090")
+	}
+	if cfg.Logging.Level != "info" {
+		t.Errorf("Logging.Level = %q, want %q", cfg.Logging.Level, "info")
+	}
+}
+
+func TestLoadAppConfigFileOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	const toml = `
+[database]
+driver = "sqlite"
+name = "test.db"
+
+[admin]
+listen_addr = ":9999"
+`
+	if err := os.WriteFile(path, []byte(toml), 0o600); err != nil {
+		t.Fatalf("writing test config: %v", err)
+	}
+	t.Setenv("CONFIG_PATH", path)
+
+	cfg, err := LoadAppConfig()
+	if err != nil {
+		t.Fatalf("LoadAppConfig() error = %v, want nil", err)
+	}
+
+	if cfg.Database.Driver != "sqlite" {
+		t.Errorf("Database.Driver = %q, want %q", cfg.Database.Driver, "sqlite")
+	}
+	if cfg.Database.Name != "test.db" {
+		t.Errorf("Database.Name = %q, want %q", cfg.Database.Name, "test.db")
+	}
+	if cfg.Admin.ListenAddr != ":9999" {
+		t.Errorf("Admin.ListenAddr = %q, want %q", cfg.Admin.ListenAddr, ":9999")
+	}
+	// Untouched by the file, should still carry its default.
+	if cfg.Logging.Level != "info" {
+		t.Errorf("Logging.Level = %q, want %q", cfg.Logging.Level, "info")
+	}
+}
+
+func TestLoadAppConfigEnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	const toml = `
+[database]
+driver = "sqlite"
+host = "file-host"
+`
+	if err := os.WriteFile(path, []byte(toml), 0o600); err != nil {
+		t.Fatalf("writing test config: %v", err)
+	}
+	t.Setenv("CONFIG_PATH", path)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("DB_DRIVER", "mysql")
+
+	cfg, err := LoadAppConfig()
+	if err != nil {
+		t.Fatalf("LoadAppConfig() error = %v, want nil", err)
+	}
+
+	if cfg.Database.Host != "env-host" {
+		t.Errorf("Database.Host = %q, want env var to win over file value", cfg.Database.Host)
+	}
+	if cfg.Database.Driver != "mysql" {
+		t.Errorf("Database.Driver = %q, want env var to win over file value", cfg.Database.Driver)
+	}
+}
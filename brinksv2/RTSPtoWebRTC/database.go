@@ -1,99 +1,254 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
-	_ "github.com/lib/pq"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-// Database configuration - connects to the same database as the FastAPI backend
-const (
-	DBHost     = "149.200.251.12"
-	DBPort     = 5432
-	DBUser     = "husain"
-	DBPassword = "tt55oo77"
-	DBName     = "razz"
-)
-
-// Camera represents a camera from the database
+// Camera is the GORM model backing the cameras table.
 type Camera struct {
-	ID        int
-	Name      string
-	RtspMain  string
-	RtspSub   string
-	Location  string
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:255;not null"`
+	RtspMain  string `gorm:"column:rtsp_main;size:512;not null"`
+	RtspSub   string `gorm:"column:rtsp_sub;size:512"`
+	Location  string `gorm:"size:255"`
 	CreatedAt time.Time
-	UpdatedAt sql.NullTime
+	UpdatedAt time.Time
 }
 
-// LoadCamerasFromDB loads cameras from PostgreSQL and updates the config
-func LoadCamerasFromDB() error {
-	// Connection string
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		DBHost, DBPort, DBUser, DBPassword, DBName)
+func (Camera) TableName() string { return "cameras" }
+
+// RecordingSchedule, MotionEvent and StreamStat are not read yet, but are
+// migrated alongside Camera so the schema is ready for the features that
+// will populate them without a separate out-of-band migration step.
+type RecordingSchedule struct {
+	ID        uint `gorm:"primaryKey"`
+	CameraID  uint `gorm:"index;not null"`
+	StartTime string
+	EndTime   string
+	Weekdays  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type MotionEvent struct {
+	ID         uint `gorm:"primaryKey"`
+	CameraID   uint `gorm:"index;not null"`
+	DetectedAt time.Time
+	Confidence float64
+	CreatedAt  time.Time
+}
+
+type StreamStat struct {
+	ID             uint `gorm:"primaryKey"`
+	CameraID       uint `gorm:"index;not null"`
+	ViewerCount    int
+	BytesForwarded int64
+	RecordedAt     time.Time
+}
+
+var (
+	gormDB *gorm.DB
 
-	// Connect to database
-	db, err := sql.Open("postgres", connStr)
+	// knownCameras mirrors the last row we saw per camera so reconcileStreams
+	// can tell "unchanged" from "updated" without inspecting Config.Streams,
+	// which only stores stream settings, not camera metadata.
+	knownCameras   = make(map[string]Camera)
+	knownCamerasMu sync.Mutex
+)
+
+// InitDB opens the GORM connection (retrying with backoff in case the
+// database is still starting up) and runs AutoMigrate for the models this
+// service owns.
+func InitDB(cfg *AppConfig) error {
+	dialector, err := dialectorFor(cfg.Database)
 	if err != nil {
-		return fmt.Errorf("error connecting to database: %v", err)
+		return err
 	}
-	defer db.Close()
 
-	// Test connection
-	err = db.Ping()
+	conn, err := waitForDB(dialector)
 	if err != nil {
-		return fmt.Errorf("error pinging database: %v", err)
+		return fmt.Errorf("error connecting to database: %v", err)
 	}
 
-	log.Println("Connected to PostgreSQL database")
+	if err := conn.AutoMigrate(&Camera{}, &RecordingSchedule{}, &MotionEvent{}, &StreamStat{}); err != nil {
+		return fmt.Errorf("error running auto migrations: %v", err)
+	}
 
-	// Query cameras
-	query := `SELECT id, name, rtsp_main, rtsp_sub, location, created_at, updated_at FROM cameras ORDER BY id`
-	rows, err := db.Query(query)
-	if err != nil {
-		return fmt.Errorf("error querying cameras: %v", err)
+	gormDB = conn
+	slog.Info("Connected to database and applied migrations", "driver", cfg.Database.Driver)
+	return nil
+}
+
+func dialectorFor(cfg DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "", "postgres":
+		return postgres.Open(cfg.dsn()), nil
+	case "mysql":
+		return mysql.Open(cfg.mysqlDSN()), nil
+	case "sqlite":
+		return sqlite.Open(cfg.Name), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
 	}
-	defer rows.Close()
+}
 
-	// Clear existing streams and reload from database
-	Config.mutex.Lock()
-	Config.Streams = make(map[string]StreamST)
-	
-	cameraCount := 0
-	for rows.Next() {
-		var camera Camera
-		err := rows.Scan(&camera.ID, &camera.Name, &camera.RtspMain, &camera.RtspSub,
-			&camera.Location, &camera.CreatedAt, &camera.UpdatedAt)
-		if err != nil {
-			log.Printf("Error scanning camera row: %v", err)
-			continue
+// waitForDB opens the connection and retries with exponential backoff (capped
+// at maxDelay) until the database answers a ping, giving up after
+// maxAttempts attempts, so a DB that is still restarting doesn't take
+// the whole service down with it. Modeled on the postgres.Wait helper used
+// by flynn-style services.
+func waitForDB(dialector gorm.Dialector) (*gorm.DB, error) {
+	const (
+		maxAttempts  = 10
+		initialDelay = 500 * time.Millisecond
+		maxDelay     = 30 * time.Second
+	)
+
+	var lastErr error
+	delay := initialDelay
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		conn, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Warn)})
+		if err == nil {
+			sqlDB, err := conn.DB()
+			if err == nil {
+				if err = sqlDB.Ping(); err == nil {
+					return conn, nil
+				}
+			}
+			lastErr = err
+		} else {
+			lastErr = err
 		}
 
-		// Create stream ID as camera1, camera2, etc.
-		streamID := fmt.Sprintf("camera%d", camera.ID)
-		
-		// Use main stream for highest quality
-		Config.Streams[streamID] = StreamST{
-			URL:          camera.RtspMain,
-			OnDemand:     false,
-			DisableAudio: true,
-			Debug:        false,
-			Cl:           make(map[string]viewer),
+		if attempt == maxAttempts {
+			break
 		}
 
-		cameraCount++
-		log.Printf("Loaded camera: %s (ID: %d, Stream: %s, Location: %s)", 
-			camera.Name, camera.ID, streamID, camera.Location)
+		slog.Warn("Database not ready, retrying", "attempt", attempt, "max_attempts", maxAttempts, "error", lastErr, "retry_in", delay)
+		time.Sleep(delay)
+		delay *= 2
+		if delay > maxDelay {
+			delay = maxDelay
+		}
 	}
-	Config.mutex.Unlock()
 
-	if err = rows.Err(); err != nil {
-		return fmt.Errorf("error iterating camera rows: %v", err)
+	return nil, lastErr
+}
+
+// LoadCamerasFromDB queries the current camera list and reconciles it into
+// Config.Streams. It is safe to call repeatedly - on startup, on a
+// cameras_changed notification, on the periodic fallback timer, and from the
+// /admin/reload endpoint.
+func LoadCamerasFromDB() error {
+	start := time.Now()
+	defer func() { ObserveReconcileDuration(time.Since(start)) }()
+
+	var cameras []Camera
+	if err := gormDB.Order("id").Find(&cameras).Error; err != nil {
+		return fmt.Errorf("error querying cameras: %v", err)
 	}
 
-	log.Printf("Successfully loaded %d cameras from database", cameraCount)
+	latest := make(map[string]Camera, len(cameras))
+	for _, camera := range cameras {
+		baseID := fmt.Sprintf("camera%d", camera.ID)
+		latest[baseID] = camera
+	}
+
+	reconcileStreams(latest)
+	camerasLoaded.Store(true)
 	return nil
 }
+
+// reconcileStreams diffs latest (keyed by base camera id, e.g. "camera3")
+// against knownCameras and applies the minimal set of adds/updates/removals
+// to Config.Streams. Each camera registers as two sibling streams,
+// baseID+mainSuffix and baseID+subSuffix (see ResolveStreamID) so a sibling
+// whose URL is unchanged is left alone and its viewers stay connected.
+func reconcileStreams(latest map[string]Camera) {
+	knownCamerasMu.Lock()
+	defer knownCamerasMu.Unlock()
+
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+
+	added, updated, removed := 0, 0, 0
+
+	for baseID, camera := range latest {
+		prev, existed := knownCameras[baseID]
+		camLog := CameraLogger(camera.ID, baseID, camera.Location)
+		changedAny := false
+
+		// Each sibling is only rewritten if its own URL changed, so an edit
+		// to one of RtspMain/RtspSub never drops the other sibling's
+		// viewers.
+		if !existed || prev.RtspMain != camera.RtspMain {
+			registerSibling(baseID+mainSuffix, camera.RtspMain, false)
+			// The idle clock, if any, belonged to the stream at the old URL;
+			// clear it so a reconfigured stream doesn't inherit a head start
+			// toward OnDemand before it's had a chance to pick up a viewer.
+			clearIdleTracking(baseID + mainSuffix)
+			changedAny = true
+		}
+		if camera.RtspSub != "" {
+			if !existed || prev.RtspSub != camera.RtspSub {
+				registerSibling(baseID+subSuffix, camera.RtspSub, false)
+				changedAny = true
+			}
+		} else {
+			// No distinct sub stream configured: drop any previously
+			// registered sub sibling so ResolveStreamID's fallback to main
+			// is the only path, instead of opening a second upstream
+			// session against the same RTSP URL.
+			if _, hadSub := Config.Streams[baseID+subSuffix]; hadSub {
+				delete(Config.Streams, baseID+subSuffix)
+				changedAny = true
+			}
+		}
+
+		if changedAny {
+			if existed {
+				updated++
+				camLog.Info("Updated camera", "name", camera.Name)
+			} else {
+				added++
+				camLog.Info("Added camera", "name", camera.Name)
+			}
+		}
+	}
+
+	for baseID, camera := range knownCameras {
+		if _, stillPresent := latest[baseID]; !stillPresent {
+			delete(Config.Streams, baseID+mainSuffix)
+			delete(Config.Streams, baseID+subSuffix)
+			clearIdleTracking(baseID + mainSuffix)
+			removed++
+			CameraLogger(camera.ID, baseID, camera.Location).Info("Removed camera", "name", camera.Name)
+		}
+	}
+
+	knownCameras = latest
+
+	slog.Info("Reconciled cameras", "added", added, "updated", updated, "removed", removed, "total", len(latest))
+}
+
+// registerSibling writes one main/sub stream entry. Callers must hold
+// Config.mutex.
+func registerSibling(streamID, url string, onDemand bool) {
+	Config.Streams[streamID] = StreamST{
+		URL:          url,
+		OnDemand:     onDemand,
+		DisableAudio: true,
+		Debug:        false,
+		Cl:           make(map[string]viewer),
+	}
+}
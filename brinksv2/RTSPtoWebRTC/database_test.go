@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// resetCameraState clears the package-level camera bookkeeping and
+// Config.Streams so each test starts from a clean slate.
+func resetCameraState(t *testing.T) {
+	t.Helper()
+	knownCamerasMu.Lock()
+	knownCameras = make(map[string]Camera)
+	knownCamerasMu.Unlock()
+
+	Config.mutex.Lock()
+	Config.Streams = make(map[string]StreamST)
+	Config.mutex.Unlock()
+}
+
+func TestReconcileStreamsAddsBothSiblings(t *testing.T) {
+	resetCameraState(t)
+
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Front Door", RtspMain: "rtsp://cam1/main", RtspSub: "rtsp://cam1/sub"},
+	})
+
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+
+	main, ok := Config.Streams["camera1_main"]
+	if !ok {
+		t.Fatal("camera1_main was not registered")
+	}
+	if main.URL != "rtsp://cam1/main" {
+		t.Errorf("camera1_main URL = %q, want rtsp://cam1/main", main.URL)
+	}
+
+	sub, ok := Config.Streams["camera1_sub"]
+	if !ok {
+		t.Fatal("camera1_sub was not registered")
+	}
+	if sub.URL != "rtsp://cam1/sub" {
+		t.Errorf("camera1_sub URL = %q, want rtsp://cam1/sub", sub.URL)
+	}
+}
+
+func TestReconcileStreamsFallsBackToMainWhenNoSub(t *testing.T) {
+	resetCameraState(t)
+
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Front Door", RtspMain: "rtsp://cam1/main"},
+	})
+
+	Config.mutex.Lock()
+	_, hasSub := Config.Streams["camera1_sub"]
+	Config.mutex.Unlock()
+
+	if hasSub {
+		t.Error("camera1_sub should not be registered when RtspSub is empty, to avoid a duplicate upstream session")
+	}
+}
+
+func TestReconcileStreamsUpdatingOneSiblingLeavesTheOtherAlone(t *testing.T) {
+	resetCameraState(t)
+
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Front Door", RtspMain: "rtsp://cam1/main", RtspSub: "rtsp://cam1/sub"},
+	})
+
+	// Simulate an active viewer on the main stream.
+	Config.mutex.Lock()
+	main := Config.Streams["camera1_main"]
+	main.Cl["viewer-a"] = viewer{}
+	Config.Streams["camera1_main"] = main
+	Config.mutex.Unlock()
+
+	// Only the sub URL changes.
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Front Door", RtspMain: "rtsp://cam1/main", RtspSub: "rtsp://cam1/sub-2"},
+	})
+
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+
+	if got := Config.Streams["camera1_main"]; len(got.Cl) != 1 {
+		t.Errorf("camera1_main.Cl has %d entries, want 1 (viewer should not be dropped when only the sub URL changes)", len(got.Cl))
+	}
+	if got := Config.Streams["camera1_sub"].URL; got != "rtsp://cam1/sub-2" {
+		t.Errorf("camera1_sub URL = %q, want rtsp://cam1/sub-2", got)
+	}
+}
+
+func TestReconcileStreamsClearsIdleTrackingWhenMainURLChanges(t *testing.T) {
+	resetCameraState(t)
+	resetIdleState(t)
+
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Front Door", RtspMain: "rtsp://cam1/main", RtspSub: "rtsp://cam1/sub"},
+	})
+
+	// Simulate the idle reaper having already started an idle clock for the
+	// old URL's stream.
+	mainStreamIdleSinceMu.Lock()
+	mainStreamIdleSince["camera1_main"] = time.Now().Add(-time.Hour)
+	mainStreamIdleSinceMu.Unlock()
+
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Front Door", RtspMain: "rtsp://cam1/main-2", RtspSub: "rtsp://cam1/sub"},
+	})
+
+	mainStreamIdleSinceMu.Lock()
+	_, tracked := mainStreamIdleSince["camera1_main"]
+	mainStreamIdleSinceMu.Unlock()
+
+	if tracked {
+		t.Error("camera1_main's idle clock should have been cleared when RtspMain was rewritten, not inherited by the new URL")
+	}
+}
+
+func TestReconcileStreamsRemovesSiblingsForDeletedCamera(t *testing.T) {
+	resetCameraState(t)
+
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Front Door", RtspMain: "rtsp://cam1/main", RtspSub: "rtsp://cam1/sub"},
+	})
+
+	reconcileStreams(map[string]Camera{})
+
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+
+	if _, ok := Config.Streams["camera1_main"]; ok {
+		t.Error("camera1_main should have been removed")
+	}
+	if _, ok := Config.Streams["camera1_sub"]; ok {
+		t.Error("camera1_sub should have been removed")
+	}
+}
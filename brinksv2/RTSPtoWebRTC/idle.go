@@ -0,0 +1,85 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idleCheckInterval is how often WatchIdleMainStreams sweeps for idle main
+// streams. It's independent of the DB reconcile interval.
+const idleCheckInterval = 15 * time.Second
+
+var (
+	// mainStreamIdleSince tracks, per _main stream id, when its viewer count
+	// first dropped to zero. reapIdleMainStreams owns it, but reconcileStreams
+	// (database.go) also clears an entry when a stream's URL is rewritten, so
+	// it needs its own mutex rather than relying on a single writer.
+	mainStreamIdleSince   = make(map[string]time.Time)
+	mainStreamIdleSinceMu sync.Mutex
+)
+
+// clearIdleTracking drops any idle-since bookkeeping for streamID. Called
+// whenever a stream's identity effectively changes (URL rewritten, or the
+// camera removed) so a stale idle clock from before the change can't
+// immediately flip a freshly (re)configured stream to OnDemand before it had
+// a real chance to pick up a viewer.
+func clearIdleTracking(streamID string) {
+	mainStreamIdleSinceMu.Lock()
+	delete(mainStreamIdleSince, streamID)
+	mainStreamIdleSinceMu.Unlock()
+}
+
+// WatchIdleMainStreams periodically flips idle _main streams to OnDemand so
+// their upstream RTSP session is torn down when nobody is watching in full
+// quality, and flips them back as soon as a viewer reappears - the
+// underlying engine reconnects OnDemand streams transparently on the next
+// viewer.
+func WatchIdleMainStreams(cfg *AppConfig) {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		reapIdleMainStreams(cfg.Streaming.mainIdleWindow())
+	}
+}
+
+func reapIdleMainStreams(idleWindow time.Duration) {
+	now := time.Now()
+
+	mainStreamIdleSinceMu.Lock()
+	defer mainStreamIdleSinceMu.Unlock()
+
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+
+	for streamID, stream := range Config.Streams {
+		if !strings.HasSuffix(streamID, mainSuffix) {
+			continue
+		}
+
+		if len(stream.Cl) > 0 {
+			delete(mainStreamIdleSince, streamID)
+			if stream.OnDemand {
+				stream.OnDemand = false
+				Config.Streams[streamID] = stream
+				RecordReconnect(streamID)
+				slog.Info("Main stream has viewers again, bringing back online", "stream_id", streamID)
+			}
+			continue
+		}
+
+		since, tracked := mainStreamIdleSince[streamID]
+		if !tracked {
+			mainStreamIdleSince[streamID] = now
+			continue
+		}
+
+		if !stream.OnDemand && now.Sub(since) >= idleWindow {
+			stream.OnDemand = true
+			Config.Streams[streamID] = stream
+			slog.Info("Main stream idle, switching to on-demand", "stream_id", streamID, "idle_for", now.Sub(since))
+		}
+	}
+}
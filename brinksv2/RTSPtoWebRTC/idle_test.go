@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func resetIdleState(t *testing.T) {
+	t.Helper()
+	mainStreamIdleSinceMu.Lock()
+	mainStreamIdleSince = make(map[string]time.Time)
+	mainStreamIdleSinceMu.Unlock()
+}
+
+func TestReapIdleMainStreamsTransitionsToOnDemandAfterWindow(t *testing.T) {
+	resetCameraState(t)
+	resetIdleState(t)
+
+	Config.mutex.Lock()
+	Config.Streams["camera1_main"] = StreamST{URL: "rtsp://cam1/main", Cl: make(map[string]viewer)}
+	Config.mutex.Unlock()
+
+	const idleWindow = 50 * time.Millisecond
+
+	// First sweep: zero viewers, but idle clock just started - too soon to
+	// switch to OnDemand.
+	reapIdleMainStreams(idleWindow)
+	Config.mutex.Lock()
+	if Config.Streams["camera1_main"].OnDemand {
+		t.Fatal("camera1_main went OnDemand before the idle window elapsed")
+	}
+	Config.mutex.Unlock()
+
+	time.Sleep(idleWindow + 10*time.Millisecond)
+
+	reapIdleMainStreams(idleWindow)
+	Config.mutex.Lock()
+	if !Config.Streams["camera1_main"].OnDemand {
+		t.Error("camera1_main should be OnDemand after sitting idle past the idle window")
+	}
+	Config.mutex.Unlock()
+}
+
+func TestReapIdleMainStreamsBringsStreamBackOnlineForViewer(t *testing.T) {
+	resetCameraState(t)
+	resetIdleState(t)
+
+	Config.mutex.Lock()
+	stream := StreamST{URL: "rtsp://cam1/main", OnDemand: true, Cl: make(map[string]viewer)}
+	stream.Cl["viewer-a"] = viewer{}
+	Config.Streams["camera1_main"] = stream
+	Config.mutex.Unlock()
+
+	reapIdleMainStreams(time.Minute)
+
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+	if Config.Streams["camera1_main"].OnDemand {
+		t.Error("camera1_main should have been brought back online since it has a viewer")
+	}
+}
+
+func TestReapIdleMainStreamsIgnoresSubStreams(t *testing.T) {
+	resetCameraState(t)
+	resetIdleState(t)
+
+	Config.mutex.Lock()
+	Config.Streams["camera1_sub"] = StreamST{URL: "rtsp://cam1/sub", Cl: make(map[string]viewer)}
+	Config.mutex.Unlock()
+
+	reapIdleMainStreams(time.Nanosecond)
+
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+	if Config.Streams["camera1_sub"].OnDemand {
+		t.Error("_sub streams should never be switched to OnDemand by the main-stream idle reaper")
+	}
+}
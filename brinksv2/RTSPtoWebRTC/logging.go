@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// LoggingConfig controls the slog handler installed by InitLogging.
+type LoggingConfig struct {
+	Level string `toml:"level"` // "debug", "info", "warn", or "error"
+}
+
+// InitLogging installs a JSON slog handler on stdout as the process-wide
+// default logger. It wraps the handler so error/warn records also bump
+// logEventsTotal, giving operational dashboards log volume without a
+// separate log pipeline.
+func InitLogging(cfg *AppConfig) {
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: parseLevel(cfg.Logging.Level),
+	})
+	slog.SetDefault(slog.New(promLogHandler{handler}))
+}
+
+func parseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// CameraLogger returns a logger carrying camera_id, stream_id, and location
+// as attributes, so RTSP reconnects, viewer joins/leaves, and DB reload
+// events can be correlated per-camera in log aggregators.
+func CameraLogger(cameraID uint, streamID, location string) *slog.Logger {
+	return slog.Default().With(
+		slog.Uint64("camera_id", uint64(cameraID)),
+		slog.String("stream_id", streamID),
+		slog.String("location", location),
+	)
+}
+
+// promLogHandler wraps an slog.Handler and increments logEventsTotal for
+// warn/error records before delegating.
+type promLogHandler struct {
+	slog.Handler
+}
+
+func (h promLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn {
+		logEventsTotal.WithLabelValues(r.Level.String()).Inc()
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h promLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return promLogHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h promLogHandler) WithGroup(name string) slog.Handler {
+	return promLogHandler{h.Handler.WithGroup(name)}
+}
@@ -2,19 +2,34 @@ package main
 
 import (
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 )
 
 func main() {
-	// Load cameras from database
-	log.Println("Loading cameras from database...")
-	err := LoadCamerasFromDB()
+	cfg, err := LoadAppConfig()
 	if err != nil {
-		log.Fatalf("Failed to load cameras from database: %v", err)
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	InitLogging(cfg)
+
+	if err := InitDB(cfg); err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Loading cameras from database...")
+	if err := LoadCamerasFromDB(); err != nil {
+		slog.Error("Failed to load cameras from database", "error", err)
+		os.Exit(1)
+	}
+
+	go WatchCameraChanges(cfg)
+	go WatchIdleMainStreams(cfg)
+	go serveAdmin(cfg)
 	go serveHTTP()
 	go serveStreams()
 	sigs := make(chan os.Signal, 1)
@@ -22,10 +37,10 @@ func main() {
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		sig := <-sigs
-		log.Println(sig)
+		slog.Info("Received signal", "signal", sig.String())
 		done <- true
 	}()
-	log.Println("Server Start Awaiting Signal")
+	slog.Info("Server Start Awaiting Signal")
 	<-done
-	log.Println("Exiting")
+	slog.Info("Exiting")
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// logEventsTotal counts warn and error slog records by level, so operational dashboards see
+// error/warn volume without standing up a separate log pipeline. It is
+// incremented by promLogHandler in logging.go.
+var logEventsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "rtsp_bridge_log_events_total",
+		Help: "Number of warn and error log events emitted, by level.",
+	},
+	[]string{"level"},
+)
+
+var (
+	rtspReconnectsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rtsp_bridge_reconnects_total",
+			Help: "Number of RTSP reconnects, by stream.",
+		},
+		[]string{"stream_id"},
+	)
+
+	dbReconcileDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "rtsp_bridge_db_reconcile_duration_seconds",
+			Help:    "Time spent reconciling Config.Streams against the cameras table.",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		logEventsTotal,
+		rtspReconnectsTotal,
+		dbReconcileDuration,
+		activeViewersCollector,
+		lastFrameCollector,
+	)
+}
+
+// RecordReconnect is called whenever the idle reaper brings a torn-down main
+// stream back online (see reapIdleMainStreams in idle.go) - the only
+// reconnect trigger this service itself controls.
+func RecordReconnect(streamID string) { rtspReconnectsTotal.WithLabelValues(streamID).Inc() }
+
+// ObserveReconcileDuration records how long a cameras-table reconcile took.
+func ObserveReconcileDuration(d time.Duration) { dbReconcileDuration.Observe(d.Seconds()) }
+
+// activeViewerCollector reads viewer counts directly off Config.Streams at
+// scrape time rather than through push-style Inc/Dec calls, so the gauge
+// reflects reality even though the viewer-join/leave call sites live in the
+// RTP/WebRTC serving code outside this package.
+type activeViewerCollector struct{}
+
+func (activeViewerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeViewersDesc
+}
+
+func (activeViewerCollector) Collect(ch chan<- prometheus.Metric) {
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+	for streamID, stream := range Config.Streams {
+		ch <- prometheus.MustNewConstMetric(activeViewersDesc, prometheus.GaugeValue, float64(len(stream.Cl)), streamID)
+	}
+}
+
+var activeViewersDesc = prometheus.NewDesc(
+	"rtsp_bridge_active_viewers",
+	"Number of connected viewers, by stream.",
+	[]string{"stream_id"},
+	nil,
+)
+
+var activeViewersCollector = activeViewerCollector{}
+
+// lastFrameTracker records, per stream, the last time a real RTP packet was
+// forwarded to a viewer. It backs the rtsp_bridge_seconds_since_last_frame
+// gauge so alerting can catch a dead camera even when the RTSP source keeps
+// the TCP connection open. RecordFrame must be called from the actual
+// RTP/packet-forwarding path (in the stream-serving code outside this
+// package) on every packet - until that call site exists, this gauge simply
+// reports no data rather than a synthetic "alive" signal, and /readyz does
+// not depend on it (see ready in readiness.go).
+type lastFrameTracker struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func (t *lastFrameTracker) record(streamID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seen[streamID] = time.Now()
+}
+
+func (t *lastFrameTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lastFrameDesc
+}
+
+func (t *lastFrameTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for streamID, seenAt := range t.seen {
+		ch <- prometheus.MustNewConstMetric(lastFrameDesc, prometheus.GaugeValue, time.Since(seenAt).Seconds(), streamID)
+	}
+}
+
+var lastFrameDesc = prometheus.NewDesc(
+	"rtsp_bridge_seconds_since_last_frame",
+	"Seconds since an RTP packet was last forwarded, by stream. No series until the packet-forwarding path calls RecordFrame.",
+	[]string{"stream_id"},
+	nil,
+)
+
+var lastFrameCollector = &lastFrameTracker{seen: make(map[string]time.Time)}
+
+// RecordFrame marks streamID as having just forwarded a real RTP packet.
+// Call this from the packet-forwarding loop, not from periodic sweeps -
+// a sweep-based heartbeat can't distinguish a live stream from one whose
+// RTSP session is stalled but whose TCP connection is still open, which
+// defeats the point of this gauge.
+func RecordFrame(streamID string) { lastFrameCollector.record(streamID) }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const camerasChangedChannel = "cameras_changed"
+
+// WatchCameraChanges keeps Config.Streams in sync with the cameras table for
+// the lifetime of the process. It listens on the cameras_changed Postgres
+// channel for near-instant updates and also reconciles on a fixed interval
+// as a fallback in case a notification is missed (e.g. during a brief
+// connection drop).
+func WatchCameraChanges(cfg *AppConfig) {
+	ticker := time.NewTicker(cfg.Admin.reconcileInterval())
+	defer ticker.Stop()
+
+	if cfg.Database.Driver != "postgres" {
+		// LISTEN/NOTIFY is a Postgres feature; other drivers fall back to
+		// polling on the reconcile interval only.
+		slog.Info("Driver does not support LISTEN/NOTIFY, polling", "driver", cfg.Database.Driver, "interval", cfg.Admin.reconcileInterval())
+		for range ticker.C {
+			if err := LoadCamerasFromDB(); err != nil {
+				slog.Error("Error reconciling cameras on periodic check", "error", err)
+			}
+		}
+		return
+	}
+
+	listener := pq.NewListener(cfg.Database.dsn(), 10*time.Second, time.Minute, listenerEventCallback)
+	if err := listener.Listen(camerasChangedChannel); err != nil {
+		slog.Warn("Error subscribing to camera change channel, falling back to polling only", "channel", camerasChangedChannel, "error", err)
+	}
+	defer listener.Close()
+
+	// Hoisted outside the loop: a ticker created inline in the select below
+	// would be recreated (and its 90s deadline pushed back) on every other
+	// branch firing, so it would never actually elapse under normal load.
+	keepalive := time.NewTicker(90 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case notification := <-listener.Notify:
+			if notification == nil {
+				// nil means the underlying connection was lost and
+				// reconnected; reconcile immediately to catch anything
+				// missed while disconnected.
+				slog.Info("Camera listener reconnected, reconciling")
+			} else {
+				slog.Info("Received camera change notification, reconciling", "channel", camerasChangedChannel)
+			}
+			if err := LoadCamerasFromDB(); err != nil {
+				slog.Error("Error reconciling cameras after notification", "error", err)
+			}
+
+		case <-ticker.C:
+			if err := LoadCamerasFromDB(); err != nil {
+				slog.Error("Error reconciling cameras on periodic check", "error", err)
+			}
+
+		case <-keepalive.C:
+			// Ping periodically so a half-open connection is detected
+			// promptly, per pq.Listener's documented usage pattern.
+			if err := listener.Ping(); err != nil {
+				slog.Warn("Error pinging camera listener", "error", err)
+			}
+		}
+	}
+}
+
+func listenerEventCallback(ev pq.ListenerEventType, err error) {
+	if err != nil {
+		slog.Warn("Camera listener event error", "error", err)
+	}
+}
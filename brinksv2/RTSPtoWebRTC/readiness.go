@@ -0,0 +1,25 @@
+package main
+
+import "sync/atomic"
+
+// camerasLoaded flips to true the first time LoadCamerasFromDB completes
+// successfully. /readyz uses it, together with Config.Streams being
+// non-empty, to decide whether the service is ready to take traffic.
+//
+// This intentionally does not gate on rtsp_bridge_seconds_since_last_frame:
+// that gauge only has real data once the RTP/packet-forwarding path (outside
+// this package) calls RecordFrame, so gating on it here today would keep
+// /readyz from ever reporting ready.
+// Revisit once RecordFrame has a real call site.
+var camerasLoaded atomic.Bool
+
+// ready reports whether the service has loaded its camera list at least
+// once and has at least one stream configured.
+func ready() bool {
+	if !camerasLoaded.Load() {
+		return false
+	}
+	Config.mutex.Lock()
+	defer Config.mutex.Unlock()
+	return len(Config.Streams) > 0
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	mainSuffix = "_main"
+	subSuffix  = "_sub"
+)
+
+// mainBandwidthThresholdBps is the WebRTC bandwidth estimate, in bits per
+// second, at or above which a viewer is promoted from sub to main quality.
+const mainBandwidthThresholdBps = 1_000_000
+
+// QualityFromRequest decides main vs sub for a viewer requesting a bare
+// camera id (e.g. "camera3"): an explicit ?quality=main|sub query param
+// wins, otherwise a ?bw=<bps> WebRTC bandwidth estimate at or above
+// mainBandwidthThresholdBps promotes to main. Everything else defaults to
+// sub, since that's the lighter stream most viewers should get.
+func QualityFromRequest(r *http.Request) string {
+	if q := r.URL.Query().Get("quality"); q == "main" || q == "sub" {
+		return q
+	}
+	if bwStr := r.URL.Query().Get("bw"); bwStr != "" {
+		if bw, err := strconv.ParseInt(bwStr, 10, 64); err == nil && bw >= mainBandwidthThresholdBps {
+			return "main"
+		}
+	}
+	return "sub"
+}
+
+// ResolveStreamID maps a base camera id and a quality hint (from
+// QualityFromRequest) to the concrete, registered Config.Streams key. The
+// WebRTC/WHEP request handler should call this before looking up a stream
+// whenever the requested id has no _main/_sub suffix of its own. Cameras
+// without a distinct sub feed have no "_sub" entry in Config.Streams, so a
+// sub request falls back to main rather than opening a second upstream
+// session against the same RTSP URL.
+func ResolveStreamID(baseID, quality string) string {
+	if quality == "main" {
+		return baseID + mainSuffix
+	}
+
+	subID := baseID + subSuffix
+	Config.mutex.Lock()
+	_, hasSub := Config.Streams[subID]
+	Config.mutex.Unlock()
+	if hasSub {
+		return subID
+	}
+	return baseID + mainSuffix
+}
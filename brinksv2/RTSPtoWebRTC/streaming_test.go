@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQualityFromRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"default is sub", "/resolve/camera1", "sub"},
+		{"explicit quality=main wins", "/resolve/camera1?quality=main", "main"},
+		{"explicit quality=sub wins", "/resolve/camera1?quality=sub", "sub"},
+		{"invalid quality falls back to sub", "/resolve/camera1?quality=ultra", "sub"},
+		{"low bandwidth estimate stays sub", "/resolve/camera1?bw=100000", "sub"},
+		{"high bandwidth estimate promotes to main", "/resolve/camera1?bw=5000000", "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			if got := QualityFromRequest(r); got != tt.want {
+				t.Errorf("QualityFromRequest(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveStreamID(t *testing.T) {
+	resetCameraState(t)
+
+	reconcileStreams(map[string]Camera{
+		"camera1": {ID: 1, Name: "Has Sub", RtspMain: "rtsp://cam1/main", RtspSub: "rtsp://cam1/sub"},
+		"camera2": {ID: 2, Name: "No Sub", RtspMain: "rtsp://cam2/main"},
+	})
+
+	tests := []struct {
+		name    string
+		baseID  string
+		quality string
+		want    string
+	}{
+		{"main always resolves to main sibling", "camera1", "main", "camera1_main"},
+		{"sub resolves to sub sibling when registered", "camera1", "sub", "camera1_sub"},
+		{"sub falls back to main when no sub sibling exists", "camera2", "sub", "camera2_main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveStreamID(tt.baseID, tt.quality); got != tt.want {
+				t.Errorf("ResolveStreamID(%q, %q) = %q, want %q", tt.baseID, tt.quality, got, tt.want)
+			}
+		})
+	}
+}